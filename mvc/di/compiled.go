@@ -0,0 +1,123 @@
+package di
+
+import (
+	"reflect"
+	"sync"
+)
+
+type (
+	// inputAssigner is a pre-branched, ready to run assignment routine for
+	// a single input of "fn": it's decided once, at MakeFuncInjector time,
+	// whether it pulls from a BindObject or from an already resolved
+	// provider, so the hot path never has to re-check that per call.
+	inputAssigner func(args, ctx, provided []reflect.Value)
+)
+
+// compile builds s.Compiled, the closure that Call delegates to. It trades
+// the per-call `make([]reflect.Value, ...)` and the branchy range over
+// s.inputs for a pre-sized, pooled scratch buffer and a flat slice of
+// pre-bound assigners.
+//
+// When every input is a Static BindObject (no providers, no wrappers), the
+// resolved arguments never change between calls, so the assignment loop
+// itself is skipped: a single template slice is built once and cloned.
+func (s *FuncInjector) compile() {
+	if s.Length == 0 && len(s.providers) == 0 && len(s.wrappers) == 0 {
+		s.Compiled = func(ctx ...reflect.Value) []reflect.Value {
+			return s.fn.Call(nil)
+		}
+		return
+	}
+
+	if static, template := s.staticTemplate(); static {
+		s.Compiled = func(ctx ...reflect.Value) []reflect.Value {
+			args := make([]reflect.Value, s.Length)
+			copy(args, template)
+			return s.fn.Call(args)
+		}
+		return
+	}
+
+	assigners := s.compileAssigners()
+	pool := &sync.Pool{
+		New: func() interface{} {
+			return make([]reflect.Value, s.Length)
+		},
+	}
+
+	s.Compiled = func(ctx ...reflect.Value) []reflect.Value {
+		args := pool.Get().([]reflect.Value)
+		defer pool.Put(args)
+
+		provided := s.runProviders(ctx)
+		for _, assign := range assigners {
+			assign(args, ctx, provided)
+		}
+
+		if len(s.wrappers) == 0 {
+			return s.fn.Call(args)
+		}
+
+		return s.callChain(ctx, provided, args)
+	}
+}
+
+// staticTemplate reports whether every input is directly satisfied by a
+// Static BindObject, with no provider or wrapper in the mix, and, if so,
+// returns the one-time-computed argument slice to be cloned per call.
+func (s *FuncInjector) staticTemplate() (bool, []reflect.Value) {
+	if len(s.providers) > 0 || len(s.wrappers) > 0 {
+		return false, nil
+	}
+
+	for _, input := range s.inputs {
+		if input.Struct != nil || input.Object == nil || input.Object.BindType != Static {
+			return false, nil
+		}
+	}
+
+	template := make([]reflect.Value, s.Length)
+	for _, input := range s.inputs {
+		input.Object.Assign(nil, func(v reflect.Value) {
+			template[input.InputIndex] = v
+		})
+	}
+
+	return true, template
+}
+
+// compileAssigners pre-binds, once, a direct assignment routine per input
+// so that Compiled's hot path never has to decide again whether an input
+// comes from a BindObject or from a resolved provider.
+func (s *FuncInjector) compileAssigners() []inputAssigner {
+	assigners := make([]inputAssigner, len(s.inputs))
+
+	for i, input := range s.inputs {
+		index := input.InputIndex
+
+		if input.Struct != nil {
+			plan := input.Struct
+			assigners[i] = func(args, ctx, _ []reflect.Value) {
+				args[index] = assignNamedStruct(plan, ctx)
+			}
+			continue
+		}
+
+		if input.Object != nil {
+			obj := input.Object
+			assigners[i] = func(args, ctx, _ []reflect.Value) {
+				obj.Assign(ctx, func(v reflect.Value) {
+					args[index] = v
+				})
+			}
+			continue
+		}
+
+		providerIndex := input.ProviderIndex
+		assigners[i] = func(args, _, provided []reflect.Value) {
+			args[index] = provided[providerIndex]
+		}
+	}
+
+	return assigners
+}
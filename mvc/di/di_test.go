@@ -0,0 +1,114 @@
+package di
+
+import (
+	"reflect"
+	"testing"
+)
+
+// These tests are intentionally scoped to the pure, self-contained helpers
+// below: MakeFuncInjector's end-to-end resolution depends on BindObject and
+// MakeBindObject, which live outside this package and aren't exercised here.
+
+func TestRegisterProviderAndIsProviderValue(t *testing.T) {
+	fn := func() int { return 42 }
+	val := RegisterProvider(fn)
+
+	if !isProviderValue(val) {
+		t.Fatalf("expected isProviderValue to report true for a RegisterProvider value")
+	}
+
+	if isProviderValue(reflect.ValueOf(fn)) {
+		t.Fatalf("expected isProviderValue to report false for a plain, unwrapped func value")
+	}
+
+	got := val.Interface().(Provider).Func
+	if got.Interface().(func() int)() != 42 {
+		t.Fatalf("expected the wrapped func to be preserved and callable")
+	}
+}
+
+func TestRegisterNamedAndIsNamedValue(t *testing.T) {
+	val := RegisterNamed("primary", "a dsn")
+
+	if !isNamedValue(val) {
+		t.Fatalf("expected isNamedValue to report true for a RegisterNamed value")
+	}
+
+	if isNamedValue(reflect.ValueOf("a dsn")) {
+		t.Fatalf("expected isNamedValue to report false for a plain string value")
+	}
+
+	named := val.Interface().(Named)
+	if named.Name != "primary" || named.Value.Interface().(string) != "a dsn" {
+		t.Fatalf("expected the name and value to be preserved, got: %#v", named)
+	}
+}
+
+func TestIsWrapperFunc(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   interface{}
+		want bool
+	}{
+		{"niladic next, no other inputs", func(next func()) {}, true},
+		{"niladic next with a return, plus other inputs", func(s string, next func() error) error { return nil }, true},
+		{"last input is not a func", func(s string) {}, false},
+		{"last input is a func that itself takes inputs", func(cb func(int)) {}, false},
+		{"no inputs at all", func() {}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ := reflect.TypeOf(tt.fn)
+			if got := isWrapperFunc(typ); got != tt.want {
+				t.Fatalf("isWrapperFunc(%s) = %v, want %v", typ, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsProviderAssignable(t *testing.T) {
+	var (
+		stringTyp = reflect.TypeOf("")
+		intTyp    = reflect.TypeOf(0)
+		errTyp    = reflect.TypeOf((*error)(nil)).Elem()
+	)
+
+	if !isProviderAssignable(stringTyp, stringTyp) {
+		t.Fatalf("expected an identical type to be assignable")
+	}
+
+	if isProviderAssignable(stringTyp, intTyp) {
+		t.Fatalf("expected unrelated concrete types not to be assignable")
+	}
+
+	customErr := reflect.TypeOf(&namedStructPlanErr{})
+	if !isProviderAssignable(customErr, errTyp) {
+		t.Fatalf("expected a type implementing an interface to be assignable to it")
+	}
+}
+
+// namedStructPlanErr is a minimal error implementation used only to exercise
+// isProviderAssignable's interface-satisfaction branch above.
+type namedStructPlanErr struct{}
+
+func (*namedStructPlanErr) Error() string { return "boom" }
+
+func TestCloneConsumed(t *testing.T) {
+	original := map[int]bool{0: true, 2: true}
+	clone := cloneConsumed(original)
+
+	if !reflect.DeepEqual(original, clone) {
+		t.Fatalf("expected clone to start out equal to the original, got %#v want %#v", clone, original)
+	}
+
+	clone[1] = true
+	delete(clone, 0)
+
+	if original[1] {
+		t.Fatalf("expected mutating the clone not to affect the original")
+	}
+	if !original[0] {
+		t.Fatalf("expected deleting from the clone not to affect the original")
+	}
+}
@@ -9,6 +9,34 @@ type (
 	targetFuncInput struct {
 		Object     *BindObject
 		InputIndex int
+		// ProviderIndex is the index, inside the owner FuncInjector's "providers"
+		// slice, of the provider that should feed this input instead of Object.
+		// It's -1 when Object is the one that should be assigned.
+		ProviderIndex int
+		// Struct is set instead of Object when this input is a "named struct",
+		// i.e. a struct type with one or more `di:"name"` tagged fields.
+		Struct *namedStructPlan
+		// Name is the registered name that was used to resolve Object, if any,
+		// kept around only so the debug trace can report it.
+		Name string
+	}
+
+	// providerInput describes where one of a provider function's own
+	// input arguments should come from: either a concrete, caller-given
+	// value (Object) or the output of another, already resolved, provider
+	// (ProviderIndex, -1 when Object is set).
+	providerInput struct {
+		Object        *BindObject
+		ProviderIndex int
+	}
+
+	// providerPlan is a registered provider function together with the
+	// resolved plan of where each of its own inputs comes from. Plans are
+	// stored in dependency (topological) order, so that a plan never
+	// refers to a ProviderIndex that comes after it.
+	providerPlan struct {
+		Provider reflect.Value
+		Inputs   []*providerInput
 	}
 
 	// FuncInjector keeps the data that are needed in order to do the binding injection
@@ -19,6 +47,17 @@ type (
 		fn reflect.Value
 
 		inputs []*targetFuncInput
+		// providers holds the chain of provider functions that were found
+		// necessary to satisfy "fn"'s (or other providers') inputs, in the
+		// order they must be called, each one exactly once.
+		providers []*providerPlan
+		// wrappers holds the registered middleware-like providers, in the
+		// exact order they were given to MakeFuncInjector, each one wrapping
+		// the rest of the chain (the next wrapper, or "fn" for the last one).
+		wrappers []*wrapperPlan
+		// Compiled is the, pre-built at MakeFuncInjector time, zero-allocation
+		// (on the hot, non-static path: pooled, not zero) version of Call.
+		Compiled func(ctx ...reflect.Value) []reflect.Value
 		// Length is the number of the valid, final binded input arguments.
 		Length int
 		// Valid is True when `Length` is > 0, it's statically set-ed for
@@ -32,7 +71,13 @@ type (
 // MakeFuncInjector returns a new func injector, which will be the object
 // that the caller should use to bind input arguments of the "fn" function.
 //
-// The hijack and the goodFunc are optional, the "values" is the dependencies values.
+// The hijack and the goodFunc are optional, the "values" is the dependencies values,
+// a value of those can opt in as a provider, instead of a concrete value, by going
+// through RegisterProvider first, i.e `RegisterProvider(func() *sql.DB { ... })`
+// next to a `string` and an `int`, in which case its inputs are resolved,
+// recursively, against the other "values" and providers. A plain func-kind
+// value that wasn't wrapped with RegisterProvider is bound directly, like any
+// other concrete value.
 func MakeFuncInjector(fn reflect.Value, hijack Hijacker, goodFunc TypeChecker, values ...reflect.Value) *FuncInjector {
 	typ := IndirectType(fn.Type())
 	s := &FuncInjector{
@@ -40,15 +85,59 @@ func MakeFuncInjector(fn reflect.Value, hijack Hijacker, goodFunc TypeChecker, v
 	}
 
 	if !IsFunc(typ) {
+		s.compile()
 		return s
 	}
 
 	n := typ.NumIn()
 
+	// values can be concrete dependencies, provider functions, wrapper
+	// (middleware-like) functions (both opted into via RegisterProvider) or
+	// named ones (see RegisterNamed); keep them apart so the resolution
+	// strategies don't interfere with each other. A plain func-kind value
+	// that wasn't wrapped with RegisterProvider is a concrete dependency
+	// like any other, e.g. a callback or an http.HandlerFunc the caller
+	// wants injected as-is.
+	var concreteValues, providerValues, wrapperValues, namedPairs []reflect.Value
+	// names maps an index inside concreteValues to the name it was
+	// registered under, for the values that came from RegisterNamed.
+	names := make(map[int]string)
+	for _, val := range values {
+		if isProviderValue(val) {
+			fn := val.Interface().(Provider).Func
+			if isWrapperFunc(fn.Type()) {
+				wrapperValues = append(wrapperValues, fn)
+				continue
+			}
+			providerValues = append(providerValues, fn)
+			continue
+		}
+
+		if isNamedValue(val) {
+			named := val.Interface().(Named)
+			namedPairs = append(namedPairs, val)
+			names[len(concreteValues)] = named.Name
+			concreteValues = append(concreteValues, named.Value)
+			continue
+		}
+
+		concreteValues = append(concreteValues, val)
+	}
+
 	// function input can have many values of the same types,
 	// so keep track of them in order to not set a func input to a next bind value,
 	// i.e (string, string) with two different binder funcs because of the different param's name.
 	consumedValues := make(map[int]bool, n)
+	// consumedNamed tracks, separately, which of the raw named pairs were
+	// already handed out whole to a `Named`-typed input.
+	consumedNamed := make(map[int]bool, len(namedPairs))
+	// resolved caches a provider's plan index by the input type it was
+	// resolved for; together with the check of s.providers for an already
+	// planned provider, a provider needed by more than one input is only
+	// ever called once.
+	resolved := make(map[reflect.Type]int)
+	// resolving guards the recursive resolution against provider cycles.
+	resolving := make(map[reflect.Type]bool)
 
 	for i := 0; i < n; i++ {
 		inTyp := typ.In(i)
@@ -56,56 +145,250 @@ func MakeFuncInjector(fn reflect.Value, hijack Hijacker, goodFunc TypeChecker, v
 		if hijack != nil {
 			if b, ok := hijack(inTyp); ok && b != nil {
 				s.inputs = append(s.inputs, &targetFuncInput{
-					InputIndex: i,
-					Object:     b,
+					InputIndex:    i,
+					Object:        b,
+					ProviderIndex: -1,
 				})
 				continue
 			}
 		}
 
-		for valIdx, val := range values {
-			if _, shouldSkip := consumedValues[valIdx]; shouldSkip {
+		// a function can opt in to receive a raw, unassigned *Named* pair
+		// verbatim instead of the plain, unwrapped value.
+		if inTyp == namedType {
+			if b, _, ok := resolveConcreteInput(inTyp, namedPairs, nil, consumedNamed, goodFunc); ok {
+				s.inputs = append(s.inputs, &targetFuncInput{
+					InputIndex:    i,
+					Object:        b,
+					ProviderIndex: -1,
+				})
 				continue
 			}
-			inTyp := typ.In(i)
-
-			// the binded values to the func's inputs.
-			b, err := MakeBindObject(val, goodFunc)
-
-			if err != nil {
-				return s // if error stop here.
-			}
+		}
 
-			if b.IsAssignable(inTyp) {
-				// println(inTyp.String() + " is assignable to " + val.Type().String())
-				// fmt.Printf("binded input index: %d for type: %s and value: %v with pointer: %v\n",
-				// 	i, b.Type.String(), val.String(), val.Pointer())
+		// a struct input with one or more `di:"name"` tagged fields opts in
+		// to per-field, name-preferring resolution instead of being matched
+		// as a single, whole value.
+		if inTyp.Kind() == reflect.Struct {
+			if plan, ok := buildNamedStruct(inTyp, concreteValues, names, consumedValues, goodFunc); ok {
 				s.inputs = append(s.inputs, &targetFuncInput{
-					InputIndex: i,
-					Object:     &b,
+					InputIndex:    i,
+					ProviderIndex: -1,
+					Struct:        plan,
 				})
-
-				consumedValues[valIdx] = true
-				break
+				continue
 			}
 		}
+
+		if b, name, ok := resolveConcreteInput(inTyp, concreteValues, names, consumedValues, goodFunc); ok {
+			s.inputs = append(s.inputs, &targetFuncInput{
+				InputIndex:    i,
+				Object:        b,
+				ProviderIndex: -1,
+				Name:          name,
+			})
+			continue
+		}
+
+		if provIdx, ok := s.resolveProviderInput(inTyp, providerValues, concreteValues, names, consumedValues, goodFunc, resolved, resolving); ok {
+			s.inputs = append(s.inputs, &targetFuncInput{
+				InputIndex:    i,
+				ProviderIndex: provIdx,
+			})
+		}
+	}
+
+	// wrappers are resolved after "fn"'s own inputs so that the function
+	// being wrapped always gets first pick of the available values; a
+	// wrapper's own dependencies only dip into whatever is left over.
+	for _, w := range wrapperValues {
+		s.wrappers = append(s.wrappers, s.resolveWrapper(w, providerValues, concreteValues, names, consumedValues, goodFunc, resolved, resolving))
 	}
 
 	s.Length = len(s.inputs)
 	s.Valid = s.Length > 0
 
 	for i, in := range s.inputs {
-		bindmethodTyp := bindTypeString(in.Object.BindType)
 		typIn := typ.In(in.InputIndex)
 		// remember: on methods that are part of a struct (i.e controller)
 		// the input index  = 1 is the begggining instead of the 0,
 		// because the 0 is the controller receiver pointer of the method.
-		s.trace += fmt.Sprintf("[%d] %s binding: '%s' for input position: %d and type: '%s'\n", i+1, bindmethodTyp, in.Object.Type.String(), in.InputIndex, typIn.String())
+		if in.Struct != nil {
+			for _, f := range in.Struct.Fields {
+				name := f.Name
+				if name == "" {
+					name = "<type-only>"
+				}
+				s.trace += fmt.Sprintf("[%d] named struct field '%s.%s' binding: '%s' (name: '%s') for input position: %d\n",
+					i+1, typIn.String(), in.Struct.Type.Field(f.FieldIndex).Name, f.Object.Type.String(), name, in.InputIndex)
+			}
+			continue
+		}
+
+		if in.Object != nil {
+			bindmethodTyp := bindTypeString(in.Object.BindType)
+			if in.Name != "" {
+				s.trace += fmt.Sprintf("[%d] %s binding: '%s' (name: '%s') for input position: %d and type: '%s'\n", i+1, bindmethodTyp, in.Object.Type.String(), in.Name, in.InputIndex, typIn.String())
+				continue
+			}
+			s.trace += fmt.Sprintf("[%d] %s binding: '%s' for input position: %d and type: '%s'\n", i+1, bindmethodTyp, in.Object.Type.String(), in.InputIndex, typIn.String())
+			continue
+		}
+
+		provider := s.providers[in.ProviderIndex].Provider
+		s.trace += fmt.Sprintf("[%d] provider chain binding: '%s' for input position: %d and type: '%s'\n", i+1, provider.Type().String(), in.InputIndex, typIn.String())
+	}
+
+	for i, w := range s.wrappers {
+		s.trace += fmt.Sprintf("[wrapper %d] binding: '%s'\n", i+1, w.Wrapper.Type().String())
 	}
 
+	s.compile()
+
 	return s
 }
 
+// resolveConcreteInput looks up, among "values", the first not-yet-consumed
+// value that is assignable to "inTyp" and marks it as consumed. "names" is
+// optional (may be nil) and, when given, its entry for the consumed value's
+// index is returned so the caller can record which name, if any, was used.
+func resolveConcreteInput(inTyp reflect.Type, values []reflect.Value, names map[int]string, consumedValues map[int]bool, goodFunc TypeChecker) (*BindObject, string, bool) {
+	for valIdx, val := range values {
+		if consumedValues[valIdx] {
+			continue
+		}
+
+		b, err := MakeBindObject(val, goodFunc)
+		if err != nil {
+			continue
+		}
+
+		if b.IsAssignable(inTyp) {
+			consumedValues[valIdx] = true
+			return &b, names[valIdx], true
+		}
+	}
+
+	return nil, "", false
+}
+
+// resolveProviderInput tries to satisfy "inTyp" by walking the registered
+// provider functions, recursively resolving their own inputs against
+// "concreteValues" and the other providers. A provider is appended to
+// s.providers, in dependency order, the first time it's needed, and its
+// plan index is cached in "resolved" so that any further dependent input
+// reuses it instead of calling it again (dead-provider elimination follows
+// naturally, since a provider is only ever visited if something needs it).
+func (s *FuncInjector) resolveProviderInput(
+	inTyp reflect.Type,
+	providerValues, concreteValues []reflect.Value,
+	names map[int]string,
+	consumedValues map[int]bool,
+	goodFunc TypeChecker,
+	resolved map[reflect.Type]int,
+	resolving map[reflect.Type]bool,
+) (int, bool) {
+	if idx, ok := resolved[inTyp]; ok {
+		return idx, true
+	}
+
+	if resolving[inTyp] {
+		s.trace += fmt.Sprintf("provider cycle detected while resolving type: '%s'\n", inTyp.String())
+		return -1, false
+	}
+
+	for _, provider := range providerValues {
+		providerTyp := provider.Type()
+		if providerTyp.NumOut() == 0 || !isProviderAssignable(providerTyp.Out(0), inTyp) {
+			continue
+		}
+
+		// the same provider may already be planned for another input type
+		// (e.g. its concrete output type and an interface it implements).
+		for idx, planned := range s.providers {
+			if planned.Provider == provider {
+				resolved[inTyp] = idx
+				return idx, true
+			}
+		}
+
+		resolving[inTyp] = true
+
+		// work on a private copy of the consumed values so that a candidate
+		// which ends up failing never leaves the concrete values it tried
+		// along the way permanently consumed for the next candidate, or for
+		// whatever else still needs them.
+		attempt := cloneConsumed(consumedValues)
+
+		plan := &providerPlan{Provider: provider}
+		mark := len(s.providers)
+		failed := false
+		for i, pn := 0, providerTyp.NumIn(); i < pn; i++ {
+			pinTyp := providerTyp.In(i)
+
+			if b, _, ok := resolveConcreteInput(pinTyp, concreteValues, names, attempt, goodFunc); ok {
+				plan.Inputs = append(plan.Inputs, &providerInput{Object: b, ProviderIndex: -1})
+				continue
+			}
+
+			if depIdx, ok := s.resolveProviderInput(pinTyp, providerValues, concreteValues, names, attempt, goodFunc, resolved, resolving); ok {
+				plan.Inputs = append(plan.Inputs, &providerInput{ProviderIndex: depIdx})
+				continue
+			}
+
+			failed = true
+			break
+		}
+
+		delete(resolving, inTyp)
+
+		if failed {
+			// drop the dependency providers this failed candidate planned,
+			// so nothing that ends up unused is called.
+			for t, idx := range resolved {
+				if idx >= mark {
+					delete(resolved, t)
+				}
+			}
+			s.providers = s.providers[:mark]
+			continue
+		}
+
+		for valIdx := range attempt {
+			consumedValues[valIdx] = true
+		}
+
+		idx := len(s.providers)
+		s.providers = append(s.providers, plan)
+		resolved[inTyp] = idx
+		return idx, true
+	}
+
+	return -1, false
+}
+
+// cloneConsumed returns an independent copy of "consumedValues", so that
+// a candidate resolution attempt can mutate it freely without affecting
+// the caller's view until the candidate is known to have succeeded.
+func cloneConsumed(consumedValues map[int]bool) map[int]bool {
+	clone := make(map[int]bool, len(consumedValues))
+	for valIdx, consumed := range consumedValues {
+		clone[valIdx] = consumed
+	}
+
+	return clone
+}
+
+// isProviderAssignable reports whether a value produced by a provider
+// (of type "outTyp") can satisfy an input of type "inTyp".
+func isProviderAssignable(outTyp, inTyp reflect.Type) bool {
+	if outTyp == inTyp || outTyp.AssignableTo(inTyp) {
+		return true
+	}
+
+	return inTyp.Kind() == reflect.Interface && outTyp.Implements(inTyp)
+}
+
 // String returns a debug trace text.
 func (s *FuncInjector) String() string {
 	return s.trace
@@ -114,28 +397,99 @@ func (s *FuncInjector) String() string {
 // Inject accepts an already created slice of input arguments
 // and fills them, the "ctx" is optional and it's used
 // on the dependencies that depends on one or more input arguments, these are the "ctx".
+//
+// Inject only fills "in", it never calls "fn" itself, so any wrapper
+// providers registered on this injector are NOT run by it; callers that
+// need the wrapper chain around a receiver-based (method) call should use
+// Invoke instead.
 func (s *FuncInjector) Inject(in *[]reflect.Value, ctx ...reflect.Value) {
+	s.assign(in, ctx, s.runProviders(ctx))
+}
+
+// Invoke behaves like Call but accepts an already allocated "in" slice
+// instead of building one from scratch, so the caller can pre-set
+// in[0] = receiver before the rest of the inputs are resolved, exactly
+// like with Inject, the difference being that Invoke also runs the
+// registered wrapper chain around "fn", so that method (receiver-based)
+// dispatch gets the same transaction/retry/timeout semantics as Call.
+func (s *FuncInjector) Invoke(in []reflect.Value, ctx ...reflect.Value) []reflect.Value {
+	provided := s.runProviders(ctx)
+	s.assign(&in, ctx, provided)
+
+	if len(s.wrappers) == 0 {
+		return s.fn.Call(in)
+	}
+
+	return s.callChain(ctx, provided, in)
+}
+
+// assign is the shared part of Inject and Invoke: it fills "in" using the
+// already-resolved direct inputs and the, already called, "provided" values.
+func (s *FuncInjector) assign(in *[]reflect.Value, ctx []reflect.Value, provided []reflect.Value) {
 	args := *in
 	for _, input := range s.inputs {
-		input.Object.Assign(ctx, func(v reflect.Value) {
-			// fmt.Printf("assign input index: %d for value: %v\n",
-			// 	input.InputIndex, v.String())
-			args[input.InputIndex] = v
-		})
+		if input.Struct != nil {
+			args[input.InputIndex] = assignNamedStruct(input.Struct, ctx)
+			continue
+		}
+
+		if input.Object != nil {
+			input.Object.Assign(ctx, func(v reflect.Value) {
+				// fmt.Printf("assign input index: %d for value: %v\n",
+				// 	input.InputIndex, v.String())
+				args[input.InputIndex] = v
+			})
+			continue
+		}
 
+		args[input.InputIndex] = provided[input.ProviderIndex]
 	}
 
 	*in = args
 }
 
-// Call calls the "Inject" with a new slice of input arguments
-// that are computed by the length of the input argument from the MakeFuncInjector's "fn" function.
+// runProviders walks s.providers in their resolved, dependency order and
+// calls each one exactly once, returning their results indexed the same
+// way as s.providers so that both it and downstream providers' inputs
+// can look their dependency's output up by ProviderIndex.
+func (s *FuncInjector) runProviders(ctx []reflect.Value) []reflect.Value {
+	if len(s.providers) == 0 {
+		return nil
+	}
+
+	provided := make([]reflect.Value, len(s.providers))
+	for i, plan := range s.providers {
+		callArgs := make([]reflect.Value, len(plan.Inputs))
+		for j, in := range plan.Inputs {
+			if in.Object != nil {
+				in.Object.Assign(ctx, func(v reflect.Value) {
+					callArgs[j] = v
+				})
+				continue
+			}
+
+			callArgs[j] = provided[in.ProviderIndex]
+		}
+
+		provided[i] = plan.Provider.Call(callArgs)[0]
+	}
+
+	return provided
+}
+
+// Call computes the input arguments from the MakeFuncInjector's "fn" function
+// and calls it, delegating to the pre-compiled, pooled `Compiled` closure so
+// that the common case pays for neither a fresh argument slice nor a re-walk
+// of the binding decisions on every call.
+//
+// If the function needs a receiver, so the caller should be able to
+// in[0] = receiver before injection, the `Invoke` method should be used
+// instead (or `Inject`, if the wrapper chain doesn't need to run for it).
 //
-// If the function needs a receiver, so
-// the caller should be able to in[0] = receiver before injection,
-// then the `Inject` method should be used instead.
+// When one or more wrapper providers were registered, "fn" is not called
+// directly, it's invoked at the end of the wrapper chain, in registration
+// order, each wrapper deciding if, when and how many times the rest of
+// the chain actually runs.
 func (s *FuncInjector) Call(ctx ...reflect.Value) []reflect.Value {
-	in := make([]reflect.Value, s.Length, s.Length)
-	s.Inject(&in, ctx...)
-	return s.fn.Call(in)
+	return s.Compiled(ctx...)
 }
@@ -0,0 +1,131 @@
+package di
+
+import "reflect"
+
+type (
+	// Named pairs a concrete value with the name it was registered under, so
+	// MakeFuncInjector can prefer it, by name, over plain positional,
+	// type-only matching whenever more than one candidate shares the same
+	// type, e.g. two *sql.DB handles for a primary and a replica.
+	Named struct {
+		Name  string
+		Value reflect.Value
+	}
+
+	// namedStructField is the resolved plan for a single field of a
+	// "named struct" input, see buildNamedStruct.
+	namedStructField struct {
+		FieldIndex int
+		Object     *BindObject
+		// Name is the registered name of the value this field was resolved
+		// to, which, after a fallback to type-only matching, can differ from
+		// the field's tag; empty when that value was not registered by name.
+		Name string
+	}
+
+	// namedStructPlan is the resolved plan for a whole struct input whose
+	// fields opt into by-name resolution via a `di:"name"` tag.
+	namedStructPlan struct {
+		Type   reflect.Type
+		Fields []*namedStructField
+	}
+)
+
+var namedType = reflect.TypeOf(Named{})
+
+// structNameTag is the struct tag key a field can use to opt into by-name
+// resolution instead of plain type-only matching, e.g. `di:"primary"`.
+const structNameTag = "di"
+
+// RegisterNamed wraps "value" together with "name" into a reflect.Value
+// that can be passed, like any other dependency, to MakeFuncInjector's
+// "values", e.g. RegisterNamed("primary", primaryDB), RegisterNamed("replica", replicaDB).
+func RegisterNamed(name string, value interface{}) reflect.Value {
+	return reflect.ValueOf(Named{Name: name, Value: reflect.ValueOf(value)})
+}
+
+// isNamedValue reports whether "val" is the wrapper produced by RegisterNamed.
+func isNamedValue(val reflect.Value) bool {
+	return val.IsValid() && val.Type() == namedType
+}
+
+// resolveNamedInput looks up, among "values", the first not-yet-consumed
+// value registered under "wantName" that is assignable to "inTyp"; when
+// nothing was registered under that name (or "wantName" is empty) it
+// falls back to plain, type-only matching, exactly like resolveConcreteInput.
+func resolveNamedInput(inTyp reflect.Type, wantName string, values []reflect.Value, names map[int]string, consumedValues map[int]bool, goodFunc TypeChecker) (*BindObject, string, bool) {
+	if wantName != "" {
+		for valIdx, val := range values {
+			if consumedValues[valIdx] || names[valIdx] != wantName {
+				continue
+			}
+
+			b, err := MakeBindObject(val, goodFunc)
+			if err != nil {
+				continue
+			}
+
+			if b.IsAssignable(inTyp) {
+				consumedValues[valIdx] = true
+				return &b, wantName, true
+			}
+		}
+	}
+
+	return resolveConcreteInput(inTyp, values, names, consumedValues, goodFunc)
+}
+
+// buildNamedStruct resolves a "named struct" input: a struct type that
+// carries, on one or more of its fields, a `di:"name"` tag. Each tagged
+// field prefers the like-named registered value, untagged fields fall
+// back to plain type-only matching. It reports ok == false, without
+// consuming anything, when the struct has no `di` tagged field at all,
+// so the caller can fall back to treating it as a regular, single input.
+func buildNamedStruct(structTyp reflect.Type, values []reflect.Value, names map[int]string, consumedValues map[int]bool, goodFunc TypeChecker) (*namedStructPlan, bool) {
+	hasTag := false
+	for i := 0; i < structTyp.NumField(); i++ {
+		if _, ok := structTyp.Field(i).Tag.Lookup(structNameTag); ok {
+			hasTag = true
+			break
+		}
+	}
+
+	if !hasTag {
+		return nil, false
+	}
+
+	plan := &namedStructPlan{Type: structTyp}
+	for i := 0; i < structTyp.NumField(); i++ {
+		field := structTyp.Field(i)
+		if field.PkgPath != "" { // unexported, can't be Set through reflect.
+			continue
+		}
+
+		wantName := field.Tag.Get(structNameTag)
+		b, usedName, ok := resolveNamedInput(field.Type, wantName, values, names, consumedValues, goodFunc)
+		if !ok {
+			continue
+		}
+
+		plan.Fields = append(plan.Fields, &namedStructField{
+			FieldIndex: i,
+			Object:     b,
+			Name:       usedName,
+		})
+	}
+
+	return plan, true
+}
+
+// assignNamedStruct builds, at call time, the value of a named struct
+// input by assigning each of its resolved fields.
+func assignNamedStruct(plan *namedStructPlan, ctx []reflect.Value) reflect.Value {
+	v := reflect.New(plan.Type).Elem()
+	for _, f := range plan.Fields {
+		f.Object.Assign(ctx, func(got reflect.Value) {
+			v.Field(f.FieldIndex).Set(got)
+		})
+	}
+
+	return v
+}
@@ -0,0 +1,32 @@
+package di
+
+import "reflect"
+
+type (
+	// Provider marks a func value, passed to MakeFuncInjector's "values", as
+	// a provider (or, when its last input is itself a niladic func, a
+	// wrapper) dependency instead of a concrete, directly bindable one.
+	//
+	// Without this opt-in, a literal func value that a caller wants injected
+	// as-is (a callback, an http.HandlerFunc, a factory closure) could never
+	// be told apart from a func meant to be called for its return type, so
+	// every func-kind value had to be treated as a provider, breaking plain
+	// func-typed dependencies. RegisterProvider removes that ambiguity.
+	Provider struct {
+		Func reflect.Value
+	}
+)
+
+var providerType = reflect.TypeOf(Provider{})
+
+// RegisterProvider wraps "fn" so that MakeFuncInjector resolves it as a
+// provider (or wrapper) instead of trying to bind it directly, e.g.
+// RegisterProvider(func() *sql.DB { ... }).
+func RegisterProvider(fn interface{}) reflect.Value {
+	return reflect.ValueOf(Provider{Func: reflect.ValueOf(fn)})
+}
+
+// isProviderValue reports whether "val" is the wrapper produced by RegisterProvider.
+func isProviderValue(val reflect.Value) bool {
+	return val.IsValid() && val.Type() == providerType
+}
@@ -0,0 +1,144 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type (
+	// wrapperPlan is a registered "wrapper" provider together with the
+	// resolved plan of where each of its own, non-continuation inputs
+	// comes from. Wrappers are kept in registration order because, unlike
+	// regular providers, their relative order changes the observable
+	// behavior (setup/teardown nesting), not just which one happens to run.
+	wrapperPlan struct {
+		Wrapper reflect.Value
+		// Inputs holds a plan entry per wrapper input, except for the last
+		// one (the "next" continuation); an entry is nil when that input
+		// couldn't be resolved at all and is therefore left zero-valued.
+		Inputs []*providerInput
+		// NextType is the concrete function type of the trailing "next"
+		// input, i.e. `func()` or `func() T`.
+		NextType reflect.Type
+	}
+)
+
+// isWrapperFunc reports whether "typ" is a provider func whose last input
+// is itself a niladic func type, the "next" continuation of the chain,
+// which is what qualifies it as a wrapper instead of a plain provider.
+func isWrapperFunc(typ reflect.Type) bool {
+	if typ.NumIn() == 0 {
+		return false
+	}
+
+	nextTyp := typ.In(typ.NumIn() - 1)
+	return nextTyp.Kind() == reflect.Func && nextTyp.NumIn() == 0
+}
+
+// resolveWrapper builds the plan for a single wrapper provider: every
+// input but the trailing "next" one is resolved exactly like a regular
+// provider's inputs, against the concrete values and the other providers.
+func (s *FuncInjector) resolveWrapper(
+	wrapper reflect.Value,
+	providerValues, concreteValues []reflect.Value,
+	names map[int]string,
+	consumedValues map[int]bool,
+	goodFunc TypeChecker,
+	resolved map[reflect.Type]int,
+	resolving map[reflect.Type]bool,
+) *wrapperPlan {
+	typ := wrapper.Type()
+	plan := &wrapperPlan{
+		Wrapper:  wrapper,
+		NextType: typ.In(typ.NumIn() - 1),
+	}
+
+	for i, n := 0, typ.NumIn()-1; i < n; i++ {
+		inTyp := typ.In(i)
+
+		if b, _, ok := resolveConcreteInput(inTyp, concreteValues, names, consumedValues, goodFunc); ok {
+			plan.Inputs = append(plan.Inputs, &providerInput{Object: b, ProviderIndex: -1})
+			continue
+		}
+
+		if depIdx, ok := s.resolveProviderInput(inTyp, providerValues, concreteValues, names, consumedValues, goodFunc, resolved, resolving); ok {
+			plan.Inputs = append(plan.Inputs, &providerInput{ProviderIndex: depIdx})
+			continue
+		}
+
+		plan.Inputs = append(plan.Inputs, nil)
+		s.trace += fmt.Sprintf("wrapper '%s' input could not be resolved, left zero-valued: '%s'\n", typ.String(), inTyp.String())
+	}
+
+	return plan
+}
+
+// callChain invokes the registered wrappers, in registration order, around
+// the final call to "fnArgs" on s.fn: the first registered wrapper is the
+// outermost one, the last registered sits right next to "fn".
+func (s *FuncInjector) callChain(ctx, provided, fnArgs []reflect.Value) []reflect.Value {
+	call := func() []reflect.Value {
+		return s.fn.Call(fnArgs)
+	}
+
+	for i := len(s.wrappers) - 1; i >= 0; i-- {
+		call = s.bindWrapper(s.wrappers[i], ctx, provided, call)
+	}
+
+	return call()
+}
+
+// bindWrapper returns a thunk that, once invoked, assigns the wrapper's own
+// inputs, builds its "next" continuation out of "next" and calls it. The
+// wrapper may call the continuation zero, one or more times, or not at all.
+func (s *FuncInjector) bindWrapper(plan *wrapperPlan, ctx, provided []reflect.Value, next func() []reflect.Value) func() []reflect.Value {
+	wrapperTyp := plan.Wrapper.Type()
+
+	return func() []reflect.Value {
+		args := make([]reflect.Value, len(plan.Inputs)+1)
+		for i, in := range plan.Inputs {
+			switch {
+			case in == nil:
+				args[i] = reflect.Zero(wrapperTyp.In(i))
+			case in.Object != nil:
+				in.Object.Assign(ctx, func(v reflect.Value) {
+					args[i] = v
+				})
+			default:
+				args[i] = provided[in.ProviderIndex]
+			}
+		}
+
+		args[len(plan.Inputs)] = reflect.MakeFunc(plan.NextType, func([]reflect.Value) []reflect.Value {
+			return adaptReturn(next(), plan.NextType)
+		})
+
+		return plan.Wrapper.Call(args)
+	}
+}
+
+// adaptReturn reshapes "out", the values actually produced by the rest of
+// the chain (the next wrapper, or "fn" itself), so they satisfy "nextTyp"'s
+// declared return signature. A wrapper's own "next func() T" is never
+// checked against what's really downstream, so, left forwarded verbatim, a
+// mismatch (wrong count, or an incompatible type) would panic inside the
+// reflect.MakeFunc shim instead of just quietly not being what was asked
+// for; any output that doesn't line up is zero-valued instead.
+func adaptReturn(out []reflect.Value, nextTyp reflect.Type) []reflect.Value {
+	n := nextTyp.NumOut()
+	if n == 0 {
+		return nil
+	}
+
+	adapted := make([]reflect.Value, n)
+	for i := 0; i < n; i++ {
+		outTyp := nextTyp.Out(i)
+		if i < len(out) && out[i].IsValid() && out[i].Type().AssignableTo(outTyp) {
+			adapted[i] = out[i]
+			continue
+		}
+		adapted[i] = reflect.Zero(outTyp)
+	}
+
+	return adapted
+}